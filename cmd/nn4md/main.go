@@ -0,0 +1,388 @@
+/*  nn4md - neural net for pulse induction metal detector
+    Copyright (C) 2019 Alexey "FoxyLab" Voronin
+    Email:    support@foxylab.com
+    Website:  https://acdc.foxylab.com
+
+	This program is free software; you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation; either version 3 of the License, or
+    (at your option) any later version.
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+    You should have received a copy of the GNU General Public License
+    along with this program; if not, write to the Free Software
+    Foundation, Inc., 59 Temple Place, Suite 330, Boston, MA  02111-1307 USA
+
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"strconv"
+
+	"github.com/fatih/color"
+
+	"github.com/Dreamy16101976/nn4md/dataset"
+	"github.com/Dreamy16101976/nn4md/nnet"
+)
+
+const (
+	inputs         = 8                 //number of input nodes
+	defaultHiddens = 3                 //number of hidden nodes
+	outputs        = 2                 //number of output nodes
+	defaultα       = 0.1               //default learning rate
+	errThreshold   = 0.01              //MSE threshold
+	scaleIn        = 1024              //input data scaling factor
+	scaleOut       = 1.0               //output data scaling factor
+	trainFileName  = "train.dat"       //train data filename
+	validFileName  = "test.dat"        //validation data filename
+	jsonFileName   = "nn4md.json"      //JSON filename
+	curveFileName  = "nn4md_curve.csv" //learning curve CSV filename
+)
+
+// error check
+func check(e error) {
+	if e != nil {
+		panic(e)
+	}
+}
+
+// pattern is one (input, target) training or validation sample
+type pattern [2][]float64
+
+// detectorDatasetOptions describes the pulse induction detector's
+// tab-separated sample files: 8 scaled input columns followed by 2
+// scaled output columns, no header row.
+var detectorDatasetOptions = dataset.DatasetOptions{
+	Delimiter:     '\t',
+	InputColumns:  []int{0, 1, 2, 3, 4, 5, 6, 7},
+	OutputColumns: []int{8, 9},
+	Normalize:     dataset.NormalizeScale,
+	ScaleInput:    scaleIn,
+	ScaleOutput:   scaleOut,
+}
+
+// loadPatterns reads a tab-separated sample file into (input, target)
+// pairs, scaling columns per detectorDatasetOptions.
+func loadPatterns(path string) []pattern {
+	ds, err := dataset.LoadDelimited(path, detectorDatasetOptions)
+	check(err)
+	patterns := make([]pattern, len(ds.Inputs))
+	for i := range ds.Inputs {
+		patterns[i] = pattern{ds.Inputs[i], ds.Targets[i]}
+	}
+	return patterns
+}
+
+// evaluate runs net over data, returning the number of correct
+// classifications, the mean squared error, and a confusion matrix
+// (rows are the actual class, columns the predicted class).
+func evaluate(net *nnet.Network, data []pattern) (ok int, mse float64, confusion [][]int) {
+	confusion = make([][]int, outputs)
+	for i := range confusion {
+		confusion[i] = make([]int, outputs)
+	}
+	sse := 0.0
+	for _, p := range data {
+		out := net.Forward(p[0])
+		for outputIdx := range out {
+			sse += math.Pow(out[outputIdx]-p[1][outputIdx], 2)
+		}
+		actual, predicted := nnet.Argmax(p[1]), nnet.Argmax(out)
+		confusion[actual][predicted]++
+		if actual == predicted {
+			ok++
+		}
+	}
+	mse = sse / float64(len(data))
+	return ok, mse, confusion
+}
+
+// printConfusion prints a confusion matrix produced by evaluate.
+func printConfusion(confusion [][]int) {
+	fmt.Println("Confusion matrix (rows: actual, cols: predicted):")
+	for actual, row := range confusion {
+		fmt.Println(actual, row)
+	}
+}
+
+// interactiveTest repeatedly reads a sample from stdin and prints net's
+// classification of it.
+func interactiveTest(net *nnet.Network) {
+	testSet := make([]float64, inputs)
+	fmt.Println("--- TESTING ---")
+	for {
+		fmt.Println("Input test data:")
+		for i := 0; i < inputs; i++ {
+			var inp float64
+			fmt.Print(i+1, ":")
+			fmt.Scanln(&inp)
+			testSet[i] = inp / scaleIn
+		}
+		fmt.Println("Outputs:")
+		out := net.Forward(testSet)
+		fmt.Println(out)
+		fmt.Println("Answer: ", nnet.Argmax(out))
+	}
+}
+
+// runInfer loads a saved model and skips training entirely: it reports
+// accuracy and a confusion matrix against validFileName when present,
+// then drops into the interactive testing prompt.
+func runInfer(path string) {
+	net, err := nnet.LoadJSON(path)
+	check(err)
+	fmt.Println("Loaded model: ", path)
+	if _, err := os.Stat(validFileName); err == nil {
+		fmt.Println("Parse validation data...")
+		validationData := loadPatterns(validFileName)
+		ok, mse, confusion := evaluate(net, validationData)
+		fmt.Printf("MSE: %.5f\tAcc.: %d\t%.2f %%\n", mse, ok, float64(ok)/float64(len(validationData))*100.0)
+		printConfusion(confusion)
+	}
+	interactiveTest(net)
+}
+
+// params holds the network/training hyperparameters shared by the
+// single-split, cross-validation, and search training paths.
+type params struct {
+	wSeed     int64
+	hiddens   int
+	α         float64
+	batchSize int
+	l2        float64
+	dropout   float64
+	patience  int
+	optimizer string
+	momentum  float64
+}
+
+func buildNet(p params) *nnet.Network {
+	opts := []nnet.Option{
+		nnet.WithSeed(p.wSeed),
+		nnet.WithBatchSize(p.batchSize),
+		nnet.WithWeightDecay(p.l2),
+		nnet.WithDropout(p.dropout),
+	}
+	if opt := buildOptimizer(p); opt != nil {
+		opts = append(opts, nnet.WithOptimizer(opt))
+	}
+	return nnet.New([]int{inputs, p.hiddens, outputs}, opts...)
+}
+
+// buildOptimizer maps the -optimizer flag to an nnet.Optimizer. It
+// returns nil for plain SGD with no momentum, leaving the Network's
+// Optimizer unset so that Train applies plain SGD with its own lr
+// argument.
+func buildOptimizer(p params) nnet.Optimizer {
+	switch p.optimizer {
+	case "", "sgd":
+		if p.momentum == 0 {
+			return nil
+		}
+		return &nnet.SGD{LR: p.α, Momentum: p.momentum}
+	case "adam":
+		return &nnet.Adam{LR: p.α, Beta1: 0.9, Beta2: 0.999, Eps: 1e-8}
+	case "rmsprop":
+		return &nnet.RMSProp{LR: p.α, Decay: 0.9, Eps: 1e-8}
+	default:
+		check(fmt.Errorf("nn4md: unknown -optimizer %q (want sgd, adam, or rmsprop)", p.optimizer))
+		return nil
+	}
+}
+
+func main() {
+	//parameters reading
+	var seedString, hiddensString, αString, inferPath, searchSpec, optimizerName string
+	var batchSize, patience, cv int
+	var l2, dropout, momentum float64
+	flag.StringVar(&seedString, "s", "", "Seed")              //seed
+	flag.StringVar(&hiddensString, "h", "", "Hidden Neurons") //hiddens
+	flag.StringVar(&αString, "r", "", "Learning Rate")        //learning rate
+	flag.IntVar(&batchSize, "batch", 1, "Mini-batch size")    //mini-batch size
+	flag.StringVar(&inferPath, "infer", "", "Load a saved JSON model and skip training")
+	flag.Float64Var(&l2, "l2", 0, "L2 weight decay")                     //weight decay
+	flag.Float64Var(&dropout, "dropout", 0, "Hidden layer dropout rate") //dropout
+	flag.IntVar(&patience, "patience", 0, "Early-stopping patience in epochs; 0 disables it")
+	flag.IntVar(&cv, "cv", 0, "Run k-fold cross-validation instead of a single train/test split")
+	flag.StringVar(&searchSpec, "search", "", `Random hyperparameter search, e.g. "hiddens=3..12,lr=0.01..0.5,seed=1..10,trials=50"`)
+	flag.StringVar(&optimizerName, "optimizer", "sgd", `Optimizer: "sgd" (see -momentum), "adam", or "rmsprop"`)
+	flag.Float64Var(&momentum, "momentum", 0, "SGD momentum (only used with -optimizer sgd)")
+	flag.Parse()
+
+	if inferPath != "" {
+		runInfer(inferPath)
+		return
+	}
+
+	var p params
+	//get seed
+	if seedString != "" {
+		var err error
+		p.wSeed, err = strconv.ParseInt(seedString, 10, 0)
+		check(err)
+	}
+	fmt.Println("Seed: ", p.wSeed)
+	p.hiddens = defaultHiddens
+	//get hiddens
+	if hiddensString != "" {
+		hiddens64, err := strconv.ParseInt(hiddensString, 10, 0)
+		check(err)
+		p.hiddens = int(hiddens64)
+	}
+	fmt.Println("Hidden Neurons: ", p.hiddens)
+	p.α = defaultα
+	//get learning rate
+	if αString != "" {
+		var err error
+		p.α, err = strconv.ParseFloat(αString, 64)
+		check(err)
+	}
+	fmt.Println("Learning Rate: ", p.α)
+	p.batchSize = batchSize
+	p.l2 = l2
+	p.dropout = dropout
+	p.patience = patience
+	p.optimizer = optimizerName
+	p.momentum = momentum
+	fmt.Println("Batch Size: ", p.batchSize)
+	fmt.Println("L2 Weight Decay: ", p.l2)
+	fmt.Println("Dropout: ", p.dropout)
+	fmt.Println("Patience: ", p.patience)
+	fmt.Println("Optimizer: ", p.optimizer)
+	if p.optimizer == "" || p.optimizer == "sgd" {
+		fmt.Println("Momentum: ", p.momentum)
+	}
+
+	if searchSpec != "" {
+		runSearch(searchSpec, p)
+		return
+	}
+	if cv > 0 {
+		runCrossValidate(p, cv)
+		return
+	}
+	runTrain(p)
+}
+
+// runCrossValidate reports mean/stddev accuracy and MSE across k
+// stratified folds of the combined train+validation data, superseding
+// the single train/test split.
+func runCrossValidate(p params, k int) {
+	fmt.Println("--- CROSS-VALIDATION ---")
+	fmt.Println("Parse train data...")
+	train := loadPatterns(trainFileName)
+	fmt.Println("Parse validation data...")
+	valid := loadPatterns(validFileName)
+	patterns := make([][2][]float64, 0, len(train)+len(valid))
+	for _, s := range append(train, valid...) {
+		patterns = append(patterns, s)
+	}
+
+	const cvEpochs = 200
+	results, err := nnet.CrossValidate(patterns, k, cvEpochs, p.α, func() *nnet.Network { return buildNet(p) })
+	check(err)
+	for i, r := range results {
+		fmt.Printf("Fold %d\tMSE: %.5f\tAcc.: %.2f %%\n", i+1, r.MSE, r.Accuracy*100.0)
+		printConfusion(r.Confusion)
+	}
+	meanAcc, stddevAcc, meanMSE, stddevMSE := nnet.FoldStats(results)
+	fmt.Printf("Mean Acc.: %.2f %% (±%.2f)\tMean MSE: %.5f (±%.5f)\n", meanAcc*100.0, stddevAcc*100.0, meanMSE, stddevMSE)
+}
+
+func runTrain(p params) {
+	net := buildNet(p)
+	α, patience := p.α, p.patience
+	fmt.Println("Parse train data...")
+	patterns := loadPatterns(trainFileName)
+	//shuffle learning data
+	fmt.Println("Shuffle...")
+	shuffleRand := rand.New(rand.NewSource(7777))
+	for i := len(patterns) - 1; i > 0; i-- {
+		j := shuffleRand.Intn(i + 1)
+		patterns[i], patterns[j] = patterns[j], patterns[i]
+	}
+	fmt.Println("Parse validation data...")
+	validationData := loadPatterns(validFileName)
+	curveFile, err := os.Create(curveFileName)
+	check(err)
+	defer curveFile.Close()
+	fmt.Fprintln(curveFile, "epoch,train_mse,val_mse,val_acc")
+	//training
+	fmt.Println("--- TRAINING ---")
+	epoch := 0
+	iteration := 0
+	lSSE := 0.0
+	lMSE := 0.0
+	tMSE := 0.0
+	ok := 0
+	bestValMSE := math.Inf(1)
+	staleEpochs := 0
+	var best *nnet.Snapshot
+	for {
+		//train
+		lSSE += net.Train(patterns[iteration][0], patterns[iteration][1], α)
+		//validation
+		iteration++
+		if iteration == len(patterns) {
+			//epoch
+			epoch++
+			lMSE = lSSE / float64(len(patterns))
+			ok, tMSE, _ = evaluate(net, validationData)
+			fmt.Print("Epoch: ", epoch)
+			fmt.Printf("\tMSE: %.5f", lMSE)
+			fmt.Printf("\tMSE: %.5f", tMSE)
+			fmt.Print("\tAcc.: ", ok)
+			fmt.Printf("\t%.2f", float64(ok)/float64(len(validationData))*100.0)
+			fmt.Println(" %")
+			fmt.Fprintf(curveFile, "%d,%.5f,%.5f,%.5f\n", epoch, lMSE, tMSE, float64(ok)/float64(len(validationData)))
+			if patience > 0 {
+				if tMSE < bestValMSE {
+					bestValMSE = tMSE
+					staleEpochs = 0
+					best = net.Snapshot()
+				} else {
+					staleEpochs++
+					if staleEpochs >= patience {
+						fmt.Println("Early stopping: validation MSE has not improved in", patience, "epochs")
+						net.Restore(best)
+						break
+					}
+				}
+			}
+			if tMSE < errThreshold {
+				break
+			}
+			iteration = 0
+			lSSE = 0.0
+		}
+	}
+	fmt.Println("Test results:")
+	for _, p := range validationData {
+		out := net.Forward(p[0])
+		if nnet.Argmax(out) == nnet.Argmax(p[1]) {
+			color.Set(color.FgGreen)
+		} else {
+			color.Set(color.FgRed)
+		}
+		fmt.Println(nnet.Argmax(p[1]), " -> ", nnet.Argmax(out))
+		color.Unset()
+	}
+	fmt.Print("Epoch: ", epoch)
+	fmt.Printf("\tMSE: %.5f", lMSE)
+	fmt.Printf("\tMSE: %.5f", tMSE)
+	fmt.Print("\tAcc.: ", ok)
+	fmt.Printf("\t%.2f", float64(ok)/float64(len(validationData))*100.0)
+	fmt.Println(" %")
+	//saving weights to JSON file
+	err = net.SaveJSON(jsonFileName)
+	check(err)
+	interactiveTest(net)
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Dreamy16101976/nn4md/nnet"
+)
+
+// searchMaxEpochs caps how long a single search trial can train before
+// it is judged and moved on from.
+const searchMaxEpochs = 300
+
+// searchPlateauPatience is how many epochs a trial trains without a
+// validation MSE improvement before it is aborted early.
+const searchPlateauPatience = 15
+
+// searchSpec is a parsed "-search" argument: inclusive ranges for
+// hidden neurons, learning rate, and seed, plus a trial count.
+type searchSpec struct {
+	hiddensMin, hiddensMax int
+	lrMin, lrMax           float64
+	seedMin, seedMax       int64
+	trials                 int
+}
+
+// parseSearchSpec parses strings like
+// "hiddens=3..12,lr=0.01..0.5,seed=1..10,trials=50".
+func parseSearchSpec(s string) (searchSpec, error) {
+	spec := searchSpec{
+		hiddensMin: defaultHiddens, hiddensMax: defaultHiddens,
+		lrMin: defaultα, lrMax: defaultα,
+		trials: 10,
+	}
+	for _, term := range strings.Split(s, ",") {
+		kv := strings.SplitN(term, "=", 2)
+		if len(kv) != 2 {
+			return spec, fmt.Errorf("nn4md: invalid -search term %q", term)
+		}
+		key, val := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		if key == "trials" {
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				return spec, err
+			}
+			spec.trials = n
+			continue
+		}
+		bounds := strings.SplitN(val, "..", 2)
+		if len(bounds) != 2 {
+			return spec, fmt.Errorf("nn4md: invalid range %q for %q", val, key)
+		}
+		switch key {
+		case "hiddens":
+			lo, err := strconv.Atoi(bounds[0])
+			if err != nil {
+				return spec, err
+			}
+			hi, err := strconv.Atoi(bounds[1])
+			if err != nil {
+				return spec, err
+			}
+			if hi < lo {
+				return spec, fmt.Errorf("nn4md: invalid range %q for %q: max below min", val, key)
+			}
+			spec.hiddensMin, spec.hiddensMax = lo, hi
+		case "lr":
+			lo, err := strconv.ParseFloat(bounds[0], 64)
+			if err != nil {
+				return spec, err
+			}
+			hi, err := strconv.ParseFloat(bounds[1], 64)
+			if err != nil {
+				return spec, err
+			}
+			if hi < lo {
+				return spec, fmt.Errorf("nn4md: invalid range %q for %q: max below min", val, key)
+			}
+			spec.lrMin, spec.lrMax = lo, hi
+		case "seed":
+			lo, err := strconv.ParseInt(bounds[0], 10, 64)
+			if err != nil {
+				return spec, err
+			}
+			hi, err := strconv.ParseInt(bounds[1], 10, 64)
+			if err != nil {
+				return spec, err
+			}
+			if hi < lo {
+				return spec, fmt.Errorf("nn4md: invalid range %q for %q: max below min", val, key)
+			}
+			spec.seedMin, spec.seedMax = lo, hi
+		default:
+			return spec, fmt.Errorf("nn4md: unknown -search key %q", key)
+		}
+	}
+	return spec, nil
+}
+
+// trialResult is one candidate's outcome from runSearch.
+type trialResult struct {
+	Hiddens int
+	LR      float64
+	Seed    int64
+	Epochs  int
+	ValMSE  float64
+	ValAcc  float64
+}
+
+// runSearch performs a random search over specStr's ranges, training
+// each candidate (with baseP supplying the other hyperparameters) to
+// errThreshold or searchMaxEpochs, aborting early on a validation MSE
+// plateau, then writes a ranked report and saves the best model to
+// jsonFileName.
+func runSearch(specStr string, baseP params) {
+	spec, err := parseSearchSpec(specStr)
+	check(err)
+	fmt.Println("Parse train data...")
+	train := loadPatterns(trainFileName)
+	fmt.Println("Parse validation data...")
+	valid := loadPatterns(validFileName)
+
+	rng := rand.New(rand.NewSource(1))
+	results := make([]trialResult, 0, spec.trials)
+	var bestNet *nnet.Network
+	bestMSE := math.Inf(1)
+
+	fmt.Println("--- SEARCH ---")
+	for t := 0; t < spec.trials; t++ {
+		p := baseP
+		p.hiddens = spec.hiddensMin + rng.Intn(spec.hiddensMax-spec.hiddensMin+1)
+		p.α = spec.lrMin + rng.Float64()*(spec.lrMax-spec.lrMin)
+		p.wSeed = spec.seedMin + rng.Int63n(spec.seedMax-spec.seedMin+1)
+		net := buildNet(p)
+
+		mse, acc, epochs := trainUntilPlateau(net, train, valid, p.α)
+		results = append(results, trialResult{Hiddens: p.hiddens, LR: p.α, Seed: p.wSeed, Epochs: epochs, ValMSE: mse, ValAcc: acc})
+		fmt.Printf("Trial %d/%d\thiddens=%d\tlr=%.4f\tseed=%d\tepochs=%d\tvalMSE=%.5f\tvalAcc=%.2f %%\n",
+			t+1, spec.trials, p.hiddens, p.α, p.wSeed, epochs, mse, acc*100.0)
+		if mse < bestMSE {
+			bestMSE = mse
+			bestNet = net
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].ValMSE < results[j].ValMSE })
+	fmt.Println("--- SEARCH REPORT (ranked by validation MSE) ---")
+	for rank, r := range results {
+		fmt.Printf("%d.\thiddens=%d\tlr=%.4f\tseed=%d\tepochs=%d\tvalMSE=%.5f\tvalAcc=%.2f %%\n",
+			rank+1, r.Hiddens, r.LR, r.Seed, r.Epochs, r.ValMSE, r.ValAcc*100.0)
+	}
+	err = bestNet.SaveJSON(jsonFileName)
+	check(err)
+	fmt.Println("Best model saved to", jsonFileName)
+}
+
+// trainUntilPlateau trains net for up to searchMaxEpochs epochs over
+// train, evaluating against valid after every epoch, and stops early
+// once errThreshold is reached or validation MSE has not improved for
+// searchPlateauPatience epochs.
+func trainUntilPlateau(net *nnet.Network, train, valid []pattern, lr float64) (mse, acc float64, epochs int) {
+	bestMSE := math.Inf(1)
+	stale := 0
+	var ok int
+	for epochs = 1; epochs <= searchMaxEpochs; epochs++ {
+		for _, s := range train {
+			net.Train(s[0], s[1], lr)
+		}
+		ok, mse, _ = evaluate(net, valid)
+		if mse < bestMSE {
+			bestMSE = mse
+			stale = 0
+		} else {
+			stale++
+		}
+		if mse < errThreshold || stale >= searchPlateauPatience {
+			break
+		}
+	}
+	if epochs > searchMaxEpochs {
+		epochs = searchMaxEpochs
+	}
+	return mse, float64(ok) / float64(len(valid)), epochs
+}
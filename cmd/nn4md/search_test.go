@@ -0,0 +1,50 @@
+package main
+
+import "testing"
+
+func TestParseSearchSpec(t *testing.T) {
+	spec, err := parseSearchSpec("hiddens=3..12,lr=0.01..0.5,seed=1..10,trials=50")
+	if err != nil {
+		t.Fatalf("parseSearchSpec: %v", err)
+	}
+	if spec.hiddensMin != 3 || spec.hiddensMax != 12 {
+		t.Errorf("hiddens range = %d..%d, want 3..12", spec.hiddensMin, spec.hiddensMax)
+	}
+	if spec.lrMin != 0.01 || spec.lrMax != 0.5 {
+		t.Errorf("lr range = %v..%v, want 0.01..0.5", spec.lrMin, spec.lrMax)
+	}
+	if spec.seedMin != 1 || spec.seedMax != 10 {
+		t.Errorf("seed range = %d..%d, want 1..10", spec.seedMin, spec.seedMax)
+	}
+	if spec.trials != 50 {
+		t.Errorf("trials = %d, want 50", spec.trials)
+	}
+}
+
+func TestParseSearchSpecDefaults(t *testing.T) {
+	spec, err := parseSearchSpec("trials=5")
+	if err != nil {
+		t.Fatalf("parseSearchSpec: %v", err)
+	}
+	if spec.hiddensMin != defaultHiddens || spec.hiddensMax != defaultHiddens {
+		t.Errorf("hiddens range = %d..%d, want %d..%d", spec.hiddensMin, spec.hiddensMax, defaultHiddens, defaultHiddens)
+	}
+	if spec.trials != 5 {
+		t.Errorf("trials = %d, want 5", spec.trials)
+	}
+}
+
+func TestParseSearchSpecInvertedRangeErrors(t *testing.T) {
+	cases := []string{"hiddens=5..3", "lr=0.5..0.1", "seed=10..1"}
+	for _, s := range cases {
+		if _, err := parseSearchSpec(s); err == nil {
+			t.Errorf("parseSearchSpec(%q): expected an error, got nil", s)
+		}
+	}
+}
+
+func TestParseSearchSpecUnknownKey(t *testing.T) {
+	if _, err := parseSearchSpec("bogus=1..2"); err == nil {
+		t.Error("parseSearchSpec with an unknown key: expected an error, got nil")
+	}
+}
@@ -0,0 +1,271 @@
+/*  nn4md - neural net for pulse induction metal detector
+    Copyright (C) 2019 Alexey "FoxyLab" Voronin
+    Email:    support@foxylab.com
+    Website:  https://acdc.foxylab.com
+
+	This program is free software; you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation; either version 3 of the License, or
+    (at your option) any later version.
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+    You should have received a copy of the GNU General Public License
+    along with this program; if not, write to the Free Software
+    Foundation, Inc., 59 Temple Place, Suite 330, Boston, MA  02111-1307 USA
+
+*/
+
+// Package dataset loads delimited (CSV/TSV) sample files into the
+// (input, target) pairs nnet.Network trains on. It replaces the
+// hand-scanned, tab-only parser that used to be duplicated between the
+// training and validation file loaders in cmd/nn4md.
+package dataset
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Normalize selects how input columns are rescaled.
+type Normalize int
+
+const (
+	// NormalizeNone leaves values as parsed from the file.
+	NormalizeNone Normalize = iota
+	// NormalizeScale divides every value in a column by a fixed factor
+	// (DatasetOptions.ScaleInput / ScaleOutput).
+	NormalizeScale
+	// NormalizeMinMax rescales each column to [0, 1] using its min/max.
+	NormalizeMinMax
+	// NormalizeZScore rescales each column to zero mean, unit variance.
+	NormalizeZScore
+)
+
+// Stats holds the per-column statistics LoadDelimited computes while
+// normalizing a training file, so the same transform can be reapplied
+// to a validation file via DatasetOptions.Stats.
+type Stats struct {
+	Mode Normalize
+	// Scale-mode divisors, one for inputs and one for outputs.
+	ScaleInput, ScaleOutput float64
+	// Min/max or mean/stddev per input column, depending on Mode.
+	Min, Max     []float64
+	Mean, StdDev []float64
+}
+
+// DatasetOptions configures LoadDelimited.
+type DatasetOptions struct {
+	Delimiter rune // column separator; defaults to '\t'
+	HasHeader bool // skip the first row
+
+	// InputColumns and OutputColumns select, by index, which columns of
+	// each row are network inputs and training targets.
+	InputColumns  []int
+	OutputColumns []int
+
+	Normalize               Normalize
+	ScaleInput, ScaleOutput float64 // divisors used by NormalizeScale
+
+	// Stats, when set, is applied instead of being recomputed — pass the
+	// Stats returned for a training file when loading its paired
+	// validation file, so both are normalized identically.
+	Stats *Stats
+
+	// OneHot, when set, treats the single OutputColumns entry as an
+	// integer class label and expands it into a Classes-wide one-hot
+	// target vector instead of using the raw column value.
+	OneHot  bool
+	Classes int
+}
+
+// Dataset is a loaded, normalized collection of (input, target) pairs.
+type Dataset struct {
+	Inputs  [][]float64
+	Targets [][]float64
+	Stats   *Stats // the normalization actually applied (Mode NormalizeNone if none)
+}
+
+// Patterns returns the dataset in nnet's (input, target) pair form.
+func (d *Dataset) Patterns() [][2][]float64 {
+	patterns := make([][2][]float64, len(d.Inputs))
+	for i := range d.Inputs {
+		patterns[i] = [2][]float64{d.Inputs[i], d.Targets[i]}
+	}
+	return patterns
+}
+
+// LoadDelimited reads path as CSV/TSV according to opts and returns the
+// resulting (input, target) pairs. Normalization is computed from this
+// file's data unless opts.Stats is set, in which case those stats are
+// reapplied instead — the pattern for normalizing a validation file
+// with its training file's statistics.
+func LoadDelimited(path string, opts DatasetOptions) (*Dataset, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	delim := opts.Delimiter
+	if delim == 0 {
+		delim = '\t'
+	}
+	reader := csv.NewReader(f)
+	reader.Comma = delim
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("dataset: reading %s: %w", path, err)
+	}
+	if opts.HasHeader && len(rows) > 0 {
+		rows = rows[1:]
+	}
+
+	rawInputs := make([][]float64, len(rows))
+	rawTargets := make([][]float64, len(rows))
+	for i, row := range rows {
+		rawInputs[i], err = selectColumns(row, opts.InputColumns)
+		if err != nil {
+			return nil, fmt.Errorf("dataset: %s row %d: %w", path, i, err)
+		}
+		rawTargets[i], err = selectColumns(row, opts.OutputColumns)
+		if err != nil {
+			return nil, fmt.Errorf("dataset: %s row %d: %w", path, i, err)
+		}
+	}
+
+	if opts.OneHot {
+		rawTargets = oneHot(rawTargets, opts.Classes)
+	}
+
+	stats := opts.Stats
+	if stats == nil {
+		stats = computeStats(opts.Normalize, rawInputs, opts.ScaleInput, opts.ScaleOutput)
+	}
+	applyNormalize(stats, rawInputs, rawTargets)
+
+	return &Dataset{Inputs: rawInputs, Targets: rawTargets, Stats: stats}, nil
+}
+
+func selectColumns(row []string, cols []int) ([]float64, error) {
+	values := make([]float64, len(cols))
+	for i, c := range cols {
+		if c < 0 || c >= len(row) {
+			return nil, fmt.Errorf("column %d out of range (row has %d columns)", c, len(row))
+		}
+		v, err := parseFloat(row[c])
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+func parseFloat(s string) (float64, error) {
+	return strconv.ParseFloat(strings.TrimSpace(s), 64)
+}
+
+// oneHot expands a single-column integer class label into a
+// Classes-wide one-hot vector per row.
+func oneHot(targets [][]float64, classes int) [][]float64 {
+	out := make([][]float64, len(targets))
+	for i, t := range targets {
+		v := make([]float64, classes)
+		class := int(t[0])
+		if class >= 0 && class < classes {
+			v[class] = 1
+		}
+		out[i] = v
+	}
+	return out
+}
+
+func computeStats(mode Normalize, inputs [][]float64, scaleInput, scaleOutput float64) *Stats {
+	s := &Stats{Mode: mode, ScaleInput: scaleInput, ScaleOutput: scaleOutput}
+	if len(inputs) == 0 {
+		return s
+	}
+	cols := len(inputs[0])
+	switch mode {
+	case NormalizeMinMax:
+		s.Min = make([]float64, cols)
+		s.Max = make([]float64, cols)
+		for c := 0; c < cols; c++ {
+			s.Min[c] = inputs[0][c]
+			s.Max[c] = inputs[0][c]
+		}
+		for _, row := range inputs {
+			for c, v := range row {
+				if v < s.Min[c] {
+					s.Min[c] = v
+				}
+				if v > s.Max[c] {
+					s.Max[c] = v
+				}
+			}
+		}
+	case NormalizeZScore:
+		s.Mean = make([]float64, cols)
+		s.StdDev = make([]float64, cols)
+		for _, row := range inputs {
+			for c, v := range row {
+				s.Mean[c] += v
+			}
+		}
+		for c := range s.Mean {
+			s.Mean[c] /= float64(len(inputs))
+		}
+		for _, row := range inputs {
+			for c, v := range row {
+				d := v - s.Mean[c]
+				s.StdDev[c] += d * d
+			}
+		}
+		for c := range s.StdDev {
+			s.StdDev[c] = math.Sqrt(s.StdDev[c] / float64(len(inputs)))
+		}
+	}
+	return s
+}
+
+func applyNormalize(s *Stats, inputs, targets [][]float64) {
+	switch s.Mode {
+	case NormalizeScale:
+		for _, row := range inputs {
+			for c := range row {
+				if s.ScaleInput != 0 {
+					row[c] /= s.ScaleInput
+				}
+			}
+		}
+		for _, row := range targets {
+			for c := range row {
+				if s.ScaleOutput != 0 {
+					row[c] /= s.ScaleOutput
+				}
+			}
+		}
+	case NormalizeMinMax:
+		for _, row := range inputs {
+			for c := range row {
+				if rng := s.Max[c] - s.Min[c]; rng != 0 {
+					row[c] = (row[c] - s.Min[c]) / rng
+				}
+			}
+		}
+	case NormalizeZScore:
+		for _, row := range inputs {
+			for c := range row {
+				if s.StdDev[c] != 0 {
+					row[c] = (row[c] - s.Mean[c]) / s.StdDev[c]
+				}
+			}
+		}
+	}
+}
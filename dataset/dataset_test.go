@@ -0,0 +1,106 @@
+package dataset
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTempFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "data.tsv")
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadDelimitedScale(t *testing.T) {
+	path := writeTempFile(t, "10\t20\t1\t0\n20\t40\t0\t1\n")
+	ds, err := LoadDelimited(path, DatasetOptions{
+		Delimiter:     '\t',
+		InputColumns:  []int{0, 1},
+		OutputColumns: []int{2, 3},
+		Normalize:     NormalizeScale,
+		ScaleInput:    10,
+		ScaleOutput:   1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds.Inputs) != 2 {
+		t.Fatalf("got %d rows, want 2", len(ds.Inputs))
+	}
+	want := []float64{1, 2}
+	if ds.Inputs[0][0] != want[0] || ds.Inputs[0][1] != want[1] {
+		t.Errorf("Inputs[0] = %v, want %v", ds.Inputs[0], want)
+	}
+	if ds.Targets[0][0] != 1 || ds.Targets[0][1] != 0 {
+		t.Errorf("Targets[0] = %v, want [1 0]", ds.Targets[0])
+	}
+}
+
+func TestLoadDelimitedMinMaxReusesTrainingStats(t *testing.T) {
+	trainPath := writeTempFile(t, "0\t1\n10\t0\n")
+	train, err := LoadDelimited(trainPath, DatasetOptions{
+		Delimiter:     '\t',
+		InputColumns:  []int{0},
+		OutputColumns: []int{1},
+		Normalize:     NormalizeMinMax,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if train.Inputs[0][0] != 0 || train.Inputs[1][0] != 1 {
+		t.Fatalf("train min-max scaling wrong: %v", train.Inputs)
+	}
+
+	validPath := writeTempFile(t, "20\t1\n")
+	valid, err := LoadDelimited(validPath, DatasetOptions{
+		Delimiter:     '\t',
+		InputColumns:  []int{0},
+		OutputColumns: []int{1},
+		Normalize:     NormalizeMinMax,
+		Stats:         train.Stats,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 20 is twice the training max (10), so with the training stats
+	// reapplied it should scale to 2, not be re-clamped to [0, 1].
+	if valid.Inputs[0][0] != 2 {
+		t.Errorf("valid Inputs[0][0] = %v, want 2 (training stats reapplied)", valid.Inputs[0][0])
+	}
+}
+
+func TestLoadDelimitedOneHot(t *testing.T) {
+	path := writeTempFile(t, "1\t2\t0\n2\t3\t1\n")
+	ds, err := LoadDelimited(path, DatasetOptions{
+		Delimiter:     '\t',
+		InputColumns:  []int{0, 1},
+		OutputColumns: []int{2},
+		OneHot:        true,
+		Classes:       2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]float64{{1, 0}, {0, 1}}
+	for i, row := range ds.Targets {
+		if row[0] != want[i][0] || row[1] != want[i][1] {
+			t.Errorf("Targets[%d] = %v, want %v", i, row, want[i])
+		}
+	}
+}
+
+func TestLoadDelimitedColumnOutOfRange(t *testing.T) {
+	path := writeTempFile(t, "1\t2\n")
+	_, err := LoadDelimited(path, DatasetOptions{
+		Delimiter:     '\t',
+		InputColumns:  []int{0, 5},
+		OutputColumns: []int{1},
+	})
+	if err == nil {
+		t.Fatal("expected an error for an out-of-range column, got nil")
+	}
+}
@@ -0,0 +1,164 @@
+/*  nn4md - neural net for pulse induction metal detector
+    Copyright (C) 2019 Alexey "FoxyLab" Voronin
+    Email:    support@foxylab.com
+    Website:  https://acdc.foxylab.com
+
+	This program is free software; you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation; either version 3 of the License, or
+    (at your option) any later version.
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+    You should have received a copy of the GNU General Public License
+    along with this program; if not, write to the Free Software
+    Foundation, Inc., 59 Temple Place, Suite 330, Boston, MA  02111-1307 USA
+
+*/
+
+package nnet
+
+import (
+	"fmt"
+	"math"
+)
+
+// Activation transforms a layer's pre-activations and provides the
+// derivative used during backpropagation. Derivative takes the already
+// activated output (the value returned by Apply), matching the
+// convention used throughout the original logistic-only implementation.
+type Activation interface {
+	Name() string
+	Apply(z []float64) []float64
+	Derivative(y []float64) []float64
+}
+
+// Logistic is the sigmoid activation used by the original nn4md network.
+var Logistic Activation = logisticActivation{}
+
+// Tanh is the hyperbolic tangent activation.
+var Tanh Activation = tanhActivation{}
+
+// ReLU is the rectified linear unit activation.
+var ReLU Activation = reluActivation{}
+
+// Softmax normalizes a layer's outputs into a probability distribution.
+// It is intended for output layers.
+var Softmax Activation = softmaxActivation{}
+
+// activationByName resolves the Activation saved to JSON back into its
+// value, for LoadJSON.
+func activationByName(name string) (Activation, error) {
+	switch name {
+	case "logistic":
+		return Logistic, nil
+	case "tanh":
+		return Tanh, nil
+	case "relu":
+		return ReLU, nil
+	case "softmax":
+		return Softmax, nil
+	default:
+		return nil, fmt.Errorf("nnet: unknown activation %q", name)
+	}
+}
+
+type logisticActivation struct{}
+
+func (logisticActivation) Name() string { return "logistic" }
+
+func (logisticActivation) Apply(z []float64) []float64 {
+	y := make([]float64, len(z))
+	for i, v := range z {
+		y[i] = 1 / (1 + math.Exp(-v))
+	}
+	return y
+}
+
+func (logisticActivation) Derivative(y []float64) []float64 {
+	d := make([]float64, len(y))
+	for i, v := range y {
+		d[i] = v * (1 - v)
+	}
+	return d
+}
+
+type tanhActivation struct{}
+
+func (tanhActivation) Name() string { return "tanh" }
+
+func (tanhActivation) Apply(z []float64) []float64 {
+	y := make([]float64, len(z))
+	for i, v := range z {
+		y[i] = math.Tanh(v)
+	}
+	return y
+}
+
+func (tanhActivation) Derivative(y []float64) []float64 {
+	d := make([]float64, len(y))
+	for i, v := range y {
+		d[i] = 1 - v*v
+	}
+	return d
+}
+
+type reluActivation struct{}
+
+func (reluActivation) Name() string { return "relu" }
+
+func (reluActivation) Apply(z []float64) []float64 {
+	y := make([]float64, len(z))
+	for i, v := range z {
+		if v > 0 {
+			y[i] = v
+		}
+	}
+	return y
+}
+
+func (reluActivation) Derivative(y []float64) []float64 {
+	d := make([]float64, len(y))
+	for i, v := range y {
+		if v > 0 {
+			d[i] = 1
+		}
+	}
+	return d
+}
+
+type softmaxActivation struct{}
+
+func (softmaxActivation) Name() string { return "softmax" }
+
+func (softmaxActivation) Apply(z []float64) []float64 {
+	max := z[0]
+	for _, v := range z[1:] {
+		if v > max {
+			max = v
+		}
+	}
+	y := make([]float64, len(z))
+	sum := 0.0
+	for i, v := range z {
+		y[i] = math.Exp(v - max)
+		sum += y[i]
+	}
+	for i := range y {
+		y[i] /= sum
+	}
+	return y
+}
+
+// Derivative uses the same diagonal approximation as the other
+// activations rather than the full softmax Jacobian, which keeps it
+// interchangeable with the rest of the generic backprop pass in
+// Network.Train.
+func (softmaxActivation) Derivative(y []float64) []float64 {
+	d := make([]float64, len(y))
+	for i, v := range y {
+		d[i] = v * (1 - v)
+	}
+	return d
+}
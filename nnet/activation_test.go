@@ -0,0 +1,72 @@
+package nnet
+
+import (
+	"math"
+	"testing"
+)
+
+func TestActivationApplyDerivative(t *testing.T) {
+	cases := []struct {
+		name      string
+		act       Activation
+		z         []float64
+		wantApply []float64
+		wantDeriv []float64
+	}{
+		{
+			name:      "logistic",
+			act:       Logistic,
+			z:         []float64{0},
+			wantApply: []float64{0.5},
+			wantDeriv: []float64{0.25},
+		},
+		{
+			name:      "tanh",
+			act:       Tanh,
+			z:         []float64{0},
+			wantApply: []float64{0},
+			wantDeriv: []float64{1},
+		},
+		{
+			name:      "relu positive",
+			act:       ReLU,
+			z:         []float64{2},
+			wantApply: []float64{2},
+			wantDeriv: []float64{1},
+		},
+		{
+			name:      "relu negative",
+			act:       ReLU,
+			z:         []float64{-2},
+			wantApply: []float64{0},
+			wantDeriv: []float64{0},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := c.act.Apply(c.z)
+			for i := range got {
+				if math.Abs(got[i]-c.wantApply[i]) > 1e-9 {
+					t.Errorf("Apply(%v) = %v, want %v", c.z, got, c.wantApply)
+				}
+			}
+			deriv := c.act.Derivative(got)
+			for i := range deriv {
+				if math.Abs(deriv[i]-c.wantDeriv[i]) > 1e-9 {
+					t.Errorf("Derivative(%v) = %v, want %v", got, deriv, c.wantDeriv)
+				}
+			}
+		})
+	}
+}
+
+func TestSoftmaxSumsToOne(t *testing.T) {
+	y := Softmax.Apply([]float64{1, 2, 3})
+	sum := 0.0
+	for _, v := range y {
+		sum += v
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Errorf("softmax output sums to %v, want 1", sum)
+	}
+}
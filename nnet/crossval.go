@@ -0,0 +1,166 @@
+/*  nn4md - neural net for pulse induction metal detector
+    Copyright (C) 2019 Alexey "FoxyLab" Voronin
+    Email:    support@foxylab.com
+    Website:  https://acdc.foxylab.com
+
+	This program is free software; you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation; either version 3 of the License, or
+    (at your option) any later version.
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+    You should have received a copy of the GNU General Public License
+    along with this program; if not, write to the Free Software
+    Foundation, Inc., 59 Temple Place, Suite 330, Boston, MA  02111-1307 USA
+
+*/
+
+package nnet
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// FoldResult summarizes one fold of a CrossValidate run.
+type FoldResult struct {
+	Accuracy  float64
+	MSE       float64
+	Confusion [][]int
+}
+
+// CrossValidate partitions patterns into k stratified folds (each fold
+// keeping the same per-class ratio as the full set), trains a fresh
+// network per fold via buildFn on the other k-1 folds for the given
+// number of epochs, and evaluates it on the held-out fold. It returns
+// an error instead of running if k isn't satisfiable by the dataset:
+// k must be at least 2, and no larger than the smallest class's sample
+// count, or some folds would end up with no members of that class.
+func CrossValidate(patterns [][2][]float64, k, epochs int, lr float64, buildFn func() *Network) ([]FoldResult, error) {
+	if k < 2 {
+		return nil, fmt.Errorf("nnet: CrossValidate: k must be at least 2, got %d", k)
+	}
+	minClass, err := minClassCount(patterns)
+	if err != nil {
+		return nil, err
+	}
+	if k > minClass {
+		return nil, fmt.Errorf("nnet: CrossValidate: k=%d exceeds the smallest class's sample count (%d); reduce k", k, minClass)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	folds := stratifiedFolds(patterns, k, rng)
+
+	results := make([]FoldResult, k)
+	for f := 0; f < k; f++ {
+		heldOut := make(map[int]bool, len(folds[f]))
+		for _, idx := range folds[f] {
+			heldOut[idx] = true
+		}
+		var trainSet, testSet [][2][]float64
+		for i, p := range patterns {
+			if heldOut[i] {
+				testSet = append(testSet, p)
+			} else {
+				trainSet = append(trainSet, p)
+			}
+		}
+
+		net := buildFn()
+		for e := 0; e < epochs; e++ {
+			for _, p := range trainSet {
+				net.Train(p[0], p[1], lr)
+			}
+		}
+
+		ok, mse, confusion := evaluatePatterns(net, testSet)
+		results[f] = FoldResult{Accuracy: float64(ok) / float64(len(testSet)), MSE: mse, Confusion: confusion}
+	}
+	return results, nil
+}
+
+// minClassCount returns the number of samples in patterns' smallest
+// class, by the same argmax-of-target grouping stratifiedFolds uses.
+func minClassCount(patterns [][2][]float64) (int, error) {
+	counts := map[int]int{}
+	for _, p := range patterns {
+		counts[Argmax(p[1])]++
+	}
+	if len(counts) == 0 {
+		return 0, fmt.Errorf("nnet: CrossValidate: no patterns to split")
+	}
+	min := -1
+	for _, c := range counts {
+		if min == -1 || c < min {
+			min = c
+		}
+	}
+	return min, nil
+}
+
+// FoldStats returns the mean and standard deviation of accuracy and MSE
+// across a CrossValidate run.
+func FoldStats(results []FoldResult) (meanAcc, stddevAcc, meanMSE, stddevMSE float64) {
+	n := float64(len(results))
+	for _, r := range results {
+		meanAcc += r.Accuracy
+		meanMSE += r.MSE
+	}
+	meanAcc /= n
+	meanMSE /= n
+	for _, r := range results {
+		stddevAcc += (r.Accuracy - meanAcc) * (r.Accuracy - meanAcc)
+		stddevMSE += (r.MSE - meanMSE) * (r.MSE - meanMSE)
+	}
+	stddevAcc = math.Sqrt(stddevAcc / n)
+	stddevMSE = math.Sqrt(stddevMSE / n)
+	return meanAcc, stddevAcc, meanMSE, stddevMSE
+}
+
+// stratifiedFolds groups pattern indices by their target class (argmax
+// of the one-hot target) and deals each class round-robin across k
+// folds, so every fold keeps roughly the same class ratio as the whole
+// set.
+func stratifiedFolds(patterns [][2][]float64, k int, rng *rand.Rand) [][]int {
+	byClass := map[int][]int{}
+	for i, p := range patterns {
+		c := Argmax(p[1])
+		byClass[c] = append(byClass[c], i)
+	}
+	folds := make([][]int, k)
+	for _, idxs := range byClass {
+		rng.Shuffle(len(idxs), func(i, j int) { idxs[i], idxs[j] = idxs[j], idxs[i] })
+		for i, idx := range idxs {
+			folds[i%k] = append(folds[i%k], idx)
+		}
+	}
+	return folds
+}
+
+// evaluatePatterns runs net over patterns, returning the number of
+// correct classifications, the mean squared error, and a confusion
+// matrix (rows are the actual class, columns the predicted class).
+func evaluatePatterns(net *Network, patterns [][2][]float64) (ok int, mse float64, confusion [][]int) {
+	classes := len(net.Layers[len(net.Layers)-1].Weights)
+	confusion = make([][]int, classes)
+	for i := range confusion {
+		confusion[i] = make([]int, classes)
+	}
+	sse := 0.0
+	for _, p := range patterns {
+		out := net.Forward(p[0])
+		for i := range out {
+			sse += (out[i] - p[1][i]) * (out[i] - p[1][i])
+		}
+		actual, predicted := Argmax(p[1]), Argmax(out)
+		confusion[actual][predicted]++
+		if actual == predicted {
+			ok++
+		}
+	}
+	mse = sse / float64(len(patterns))
+	return ok, mse, confusion
+}
@@ -0,0 +1,46 @@
+package nnet
+
+import "testing"
+
+func balancedPatterns(n int) [][2][]float64 {
+	patterns := make([][2][]float64, n)
+	for i := range patterns {
+		if i%2 == 0 {
+			patterns[i] = [2][]float64{{float64(i)}, {1, 0}}
+		} else {
+			patterns[i] = [2][]float64{{float64(i)}, {0, 1}}
+		}
+	}
+	return patterns
+}
+
+func TestCrossValidateRejectsUnsatisfiableK(t *testing.T) {
+	patterns := balancedPatterns(20) // 10 samples per class
+	buildFn := func() *Network { return New([]int{1, 4, 2}) }
+
+	if _, err := CrossValidate(patterns, 200, 1, 0.1, buildFn); err == nil {
+		t.Error("CrossValidate(k=200) over 20 patterns: expected an error, got nil")
+	}
+	if _, err := CrossValidate(patterns, 1, 1, 0.1, buildFn); err == nil {
+		t.Error("CrossValidate(k=1): expected an error, got nil")
+	}
+	if _, err := CrossValidate(patterns, 5, 1, 0.1, buildFn); err != nil {
+		t.Errorf("CrossValidate(k=5) over 20 balanced patterns: unexpected error %v", err)
+	}
+}
+
+func TestCrossValidateProducesOneResultPerFold(t *testing.T) {
+	patterns := balancedPatterns(20)
+	results, err := CrossValidate(patterns, 4, 1, 0.1, func() *Network { return New([]int{1, 4, 2}) })
+	if err != nil {
+		t.Fatalf("CrossValidate: %v", err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("got %d fold results, want 4", len(results))
+	}
+	for i, r := range results {
+		if r.Accuracy < 0 || r.Accuracy > 1 {
+			t.Errorf("fold %d: Accuracy = %v, want in [0,1]", i, r.Accuracy)
+		}
+	}
+}
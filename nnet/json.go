@@ -0,0 +1,81 @@
+/*  nn4md - neural net for pulse induction metal detector
+    Copyright (C) 2019 Alexey "FoxyLab" Voronin
+    Email:    support@foxylab.com
+    Website:  https://acdc.foxylab.com
+
+	This program is free software; you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation; either version 3 of the License, or
+    (at your option) any later version.
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+    You should have received a copy of the GNU General Public License
+    along with this program; if not, write to the Free Software
+    Foundation, Inc., 59 Temple Place, Suite 330, Boston, MA  02111-1307 USA
+
+*/
+
+package nnet
+
+import (
+	"encoding/json"
+	"math/rand"
+	"os"
+)
+
+// jsonLayer is the on-disk representation of one Network Layer.
+type jsonLayer struct {
+	Activation string      `json:"activation"`
+	Neurons    int         `json:"neurons"`
+	Weights    [][]float64 `json:"weights"`
+	Biases     []float64   `json:"biases"`
+}
+
+// jsonModel is the on-disk representation of a Network.
+type jsonModel struct {
+	Layers []jsonLayer `json:"layers"`
+}
+
+// SaveJSON writes the network's topology, activations, and weights to
+// path so it can be reconstructed later.
+func (n *Network) SaveJSON(path string) error {
+	model := jsonModel{Layers: make([]jsonLayer, len(n.Layers))}
+	for i, l := range n.Layers {
+		model.Layers[i] = jsonLayer{
+			Activation: l.Activation.Name(),
+			Neurons:    len(l.Weights),
+			Weights:    l.Weights,
+			Biases:     l.Biases,
+		}
+	}
+	data, err := json.Marshal(model)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadJSON reconstructs a Network previously written by SaveJSON,
+// restoring its topology, activations, and weights.
+func LoadJSON(path string) (*Network, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var model jsonModel
+	if err := json.Unmarshal(data, &model); err != nil {
+		return nil, err
+	}
+	net := &Network{Layers: make([]*Layer, len(model.Layers)), rng: rand.New(rand.NewSource(1))}
+	for i, jl := range model.Layers {
+		act, err := activationByName(jl.Activation)
+		if err != nil {
+			return nil, err
+		}
+		net.Layers[i] = &Layer{Weights: jl.Weights, Biases: jl.Biases, Activation: act}
+	}
+	net.resetBatch()
+	return net, nil
+}
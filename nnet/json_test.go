@@ -0,0 +1,50 @@
+package nnet
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveLoadJSONRoundTrip(t *testing.T) {
+	net := New([]int{2, 3, 1}, WithSeed(1), WithActivations(Tanh, Logistic))
+	input := []float64{0.4, -0.2}
+	want := net.Forward(input)
+
+	path := filepath.Join(t.TempDir(), "model.json")
+	if err := net.SaveJSON(path); err != nil {
+		t.Fatalf("SaveJSON: %v", err)
+	}
+
+	loaded, err := LoadJSON(path)
+	if err != nil {
+		t.Fatalf("LoadJSON: %v", err)
+	}
+	got := loaded.Forward(input)
+	if len(got) != len(want) {
+		t.Fatalf("Forward after round-trip returned %d outputs, want %d", len(got), len(want))
+	}
+	for i := range got {
+		if math.Abs(got[i]-want[i]) > 1e-12 {
+			t.Errorf("Forward after round-trip = %v, want %v", got, want)
+		}
+	}
+	if loaded.Layers[0].Activation.Name() != "tanh" {
+		t.Errorf("loaded layer 0 activation = %q, want %q", loaded.Layers[0].Activation.Name(), "tanh")
+	}
+	if loaded.Layers[1].Activation.Name() != "logistic" {
+		t.Errorf("loaded layer 1 activation = %q, want %q", loaded.Layers[1].Activation.Name(), "logistic")
+	}
+}
+
+func TestLoadJSONUnknownActivation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.json")
+	body := []byte(`{"layers":[{"activation":"swish","neurons":1,"weights":[[1]],"biases":[0]}]}`)
+	if err := os.WriteFile(path, body, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadJSON(path); err == nil {
+		t.Error("LoadJSON with an unknown activation: expected an error, got nil")
+	}
+}
@@ -0,0 +1,389 @@
+/*  nn4md - neural net for pulse induction metal detector
+    Copyright (C) 2019 Alexey "FoxyLab" Voronin
+    Email:    support@foxylab.com
+    Website:  https://acdc.foxylab.com
+
+	This program is free software; you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation; either version 3 of the License, or
+    (at your option) any later version.
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+    You should have received a copy of the GNU General Public License
+    along with this program; if not, write to the Free Software
+    Foundation, Inc., 59 Temple Place, Suite 330, Boston, MA  02111-1307 USA
+
+*/
+
+// Package nnet implements a small, generic multilayer perceptron.
+//
+// It grew out of a fixed 3-layer, logistic-only network hard-wired for a
+// pulse induction metal detector; the API here keeps the same training
+// convention (per-sample gradient descent, SSE loss) while allowing
+// arbitrary topologies and per-layer activations.
+package nnet
+
+import "math/rand"
+
+// defaultWeightRange bounds the initial random weights, matching the
+// original detector's weightStart constant.
+const defaultWeightRange = 0.1
+
+// Layer holds the trainable state for one layer of a Network: the
+// weight matrix (Weights[j][i] is the weight from input i to neuron j),
+// the per-neuron biases, and the activation applied to the layer's
+// weighted sums.
+type Layer struct {
+	Weights    [][]float64
+	Biases     []float64
+	Activation Activation
+	// Dropout is the probability of zeroing a unit's activation during
+	// Train; it has no effect on Forward. 0 disables dropout.
+	Dropout float64
+
+	input  []float64 // last input fed into the layer, cached for Train
+	output []float64 // last activated output (pre-dropout), cached for Train
+	mask   []float64 // last dropout mask applied to output, nil if none
+}
+
+func newLayer(in, out int, act Activation, rng *rand.Rand) *Layer {
+	l := &Layer{
+		Weights:    make([][]float64, out),
+		Biases:     make([]float64, out),
+		Activation: act,
+	}
+	for j := 0; j < out; j++ {
+		l.Weights[j] = make([]float64, in)
+		for i := 0; i < in; i++ {
+			l.Weights[j][i] = rnd(rng, -defaultWeightRange, defaultWeightRange)
+		}
+		l.Biases[j] = rnd(rng, -defaultWeightRange, defaultWeightRange)
+	}
+	return l
+}
+
+func rnd(rng *rand.Rand, a, b float64) float64 {
+	return a + (b-a)*rng.Float64()
+}
+
+func mat2D(rows, cols int) [][]float64 {
+	mat := make([][]float64, rows)
+	for i := range mat {
+		mat[i] = make([]float64, cols)
+	}
+	return mat
+}
+
+func mat1D(rows int, value float64) []float64 {
+	mat := make([]float64, rows)
+	for i := range mat {
+		mat[i] = value
+	}
+	return mat
+}
+
+func (l *Layer) forward(input []float64) []float64 {
+	z := make([]float64, len(l.Weights))
+	for j, weights := range l.Weights {
+		sum := l.Biases[j]
+		for i, w := range weights {
+			sum += w * input[i]
+		}
+		z[j] = sum
+	}
+	l.input = input
+	l.output = l.Activation.Apply(z)
+	l.mask = nil
+	return l.output
+}
+
+// forwardTrain behaves like forward but additionally applies inverted
+// dropout to the layer's output when Dropout > 0, so Train sees masked
+// activations while Forward (used for inference) never does.
+func (l *Layer) forwardTrain(input []float64, rng *rand.Rand) []float64 {
+	out := l.forward(input)
+	if l.Dropout <= 0 {
+		return out
+	}
+	keep := 1 - l.Dropout
+	scale := 1 / keep
+	mask := make([]float64, len(out))
+	masked := make([]float64, len(out))
+	for i, v := range out {
+		if rng.Float64() < keep {
+			mask[i] = scale
+			masked[i] = v * scale
+		}
+	}
+	l.mask = mask
+	return masked
+}
+
+// Network is a feedforward multilayer perceptron built from an ordered
+// list of Layers.
+type Network struct {
+	Layers []*Layer
+
+	// Optimizer applies accumulated gradients to each layer. If nil,
+	// Train falls back to plain SGD using its lr argument.
+	Optimizer Optimizer
+	// BatchSize is the number of samples accumulated before Train
+	// applies an update. Values below 1 behave like 1 (per-sample SGD).
+	BatchSize int
+	// WeightDecay is the L2 regularization strength λ added to each
+	// weight's gradient before the optimizer step (subtracting λ·w from
+	// the update rule).
+	WeightDecay float64
+
+	rng        *rand.Rand
+	batchGrad  []gradAccum
+	batchCount int
+}
+
+// gradAccum accumulates gradients for one layer across a mini-batch.
+type gradAccum struct {
+	weight [][]float64
+	bias   []float64
+}
+
+// Option configures a Network at construction time.
+type Option func(*networkConfig)
+
+type networkConfig struct {
+	rng         *rand.Rand
+	activations []Activation
+	optimizer   Optimizer
+	batchSize   int
+	weightDecay float64
+	dropout     []float64
+}
+
+// WithSeed seeds the random source used for weight initialization.
+func WithSeed(seed int64) Option {
+	return func(c *networkConfig) { c.rng = rand.New(rand.NewSource(seed)) }
+}
+
+// WithActivations assigns activations to layers in order. If fewer
+// activations than layers are given, the remaining layers default to
+// Logistic.
+func WithActivations(activations ...Activation) Option {
+	return func(c *networkConfig) { c.activations = activations }
+}
+
+// WithOptimizer sets the Optimizer used by Train. Without this option,
+// Train falls back to plain SGD using its lr argument.
+func WithOptimizer(opt Optimizer) Option {
+	return func(c *networkConfig) { c.optimizer = opt }
+}
+
+// WithBatchSize sets how many samples Train accumulates gradients over
+// before applying an update. The default, 0, applies an update after
+// every sample.
+func WithBatchSize(n int) Option {
+	return func(c *networkConfig) { c.batchSize = n }
+}
+
+// WithWeightDecay sets the L2 regularization strength λ applied to
+// every layer during Train.
+func WithWeightDecay(lambda float64) Option {
+	return func(c *networkConfig) { c.weightDecay = lambda }
+}
+
+// WithDropout assigns a dropout probability to layers in order, e.g.
+// WithDropout(0.5) drops units in the first layer only, leaving later
+// layers (typically the output layer) untouched.
+func WithDropout(rates ...float64) Option {
+	return func(c *networkConfig) { c.dropout = rates }
+}
+
+// New builds a Network with the given layer sizes, e.g. New([]int{8, 16,
+// 8, 2}) creates a network with two hidden layers. len(layers) must be
+// at least 2 (an input size and an output size).
+func New(layers []int, opts ...Option) *Network {
+	cfg := &networkConfig{rng: rand.New(rand.NewSource(1))}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	net := &Network{
+		Layers:      make([]*Layer, len(layers)-1),
+		Optimizer:   cfg.optimizer,
+		BatchSize:   cfg.batchSize,
+		WeightDecay: cfg.weightDecay,
+		rng:         cfg.rng,
+	}
+	for i := 1; i < len(layers); i++ {
+		act := Logistic
+		if idx := i - 1; idx < len(cfg.activations) {
+			act = cfg.activations[idx]
+		}
+		layer := newLayer(layers[i-1], layers[i], act, cfg.rng)
+		if idx := i - 1; idx < len(cfg.dropout) {
+			layer.Dropout = cfg.dropout[idx]
+		}
+		net.Layers[i-1] = layer
+	}
+	net.resetBatch()
+	return net
+}
+
+func (n *Network) resetBatch() {
+	n.batchGrad = make([]gradAccum, len(n.Layers))
+	for i, l := range n.Layers {
+		n.batchGrad[i] = gradAccum{weight: mat2D(len(l.Weights), weightCols(l.Weights)), bias: mat1D(len(l.Biases), 0)}
+	}
+	n.batchCount = 0
+}
+
+// Forward runs input through every layer and returns the final layer's
+// activations.
+func (n *Network) Forward(input []float64) []float64 {
+	out := input
+	for _, l := range n.Layers {
+		out = l.forward(out)
+	}
+	return out
+}
+
+// Train accumulates the gradient from one (input, target) sample and,
+// once BatchSize samples have been seen (or immediately, if BatchSize is
+// 0), applies an update through Optimizer — or plain SGD using lr if no
+// Optimizer is set. It returns the sample's SSE.
+//
+// Backprop generalizes the original two-layer implementation to an
+// arbitrary number of layers: the output layer's delta is the
+// activation derivative times the error, and each hidden layer's delta
+// is its activation derivative times the downstream deltas propagated
+// back through the following layer's weights.
+func (n *Network) Train(input, targets []float64, lr float64) float64 {
+	out := input
+	for _, l := range n.Layers {
+		out = l.forwardTrain(out, n.rng)
+	}
+	output := out
+
+	sse := 0.0
+	for i, y := range output {
+		sse += (targets[i] - y) * (targets[i] - y)
+	}
+
+	deltas := make([]float64, len(output))
+	outDeriv := n.Layers[len(n.Layers)-1].Activation.Derivative(output)
+	for i, y := range output {
+		deltas[i] = outDeriv[i] * (y - targets[i])
+	}
+
+	for li := len(n.Layers) - 1; li >= 0; li-- {
+		layer := n.Layers[li]
+		nextDeltas := make([]float64, len(layer.input))
+		g := &n.batchGrad[li]
+		for j, weights := range layer.Weights {
+			d := deltas[j]
+			for i, w := range weights {
+				nextDeltas[i] += d * w
+				g.weight[j][i] += d * layer.input[i]
+			}
+			g.bias[j] += d
+		}
+		if li > 0 {
+			prev := n.Layers[li-1]
+			deriv := prev.Activation.Derivative(prev.output)
+			for i := range nextDeltas {
+				nextDeltas[i] *= deriv[i]
+				if prev.mask != nil {
+					nextDeltas[i] *= prev.mask[i]
+				}
+			}
+		}
+		deltas = nextDeltas
+	}
+
+	n.batchCount++
+	batchSize := n.BatchSize
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	if n.batchCount >= batchSize {
+		n.applyBatch(lr)
+	}
+
+	return sse
+}
+
+// applyBatch averages the accumulated gradients over the batch and
+// hands them to the Optimizer (plain SGD with rate lr if none is set).
+func (n *Network) applyBatch(lr float64) {
+	opt := n.Optimizer
+	if opt == nil {
+		opt = &SGD{LR: lr}
+	}
+	count := float64(n.batchCount)
+	for li, layer := range n.Layers {
+		g := n.batchGrad[li]
+		for j := range g.weight {
+			for i := range g.weight[j] {
+				g.weight[j][i] = g.weight[j][i]/count + n.WeightDecay*layer.Weights[j][i]
+			}
+			g.bias[j] /= count
+		}
+		opt.Update(li, layer, g.weight, g.bias)
+	}
+	n.resetBatch()
+}
+
+// Snapshot deep-copies the network's current weights and biases so they
+// can be restored later, e.g. by early stopping when validation error
+// stops improving.
+func (n *Network) Snapshot() *Snapshot {
+	s := &Snapshot{layers: make([]layerSnapshot, len(n.Layers))}
+	for i, l := range n.Layers {
+		weights := mat2D(len(l.Weights), weightCols(l.Weights))
+		for j, row := range l.Weights {
+			copy(weights[j], row)
+		}
+		biases := make([]float64, len(l.Biases))
+		copy(biases, l.Biases)
+		s.layers[i] = layerSnapshot{weights: weights, biases: biases}
+	}
+	return s
+}
+
+// Restore replaces the network's weights and biases with those held in
+// a Snapshot previously produced by Snapshot.
+func (n *Network) Restore(s *Snapshot) {
+	for i, l := range n.Layers {
+		for j, row := range s.layers[i].weights {
+			copy(l.Weights[j], row)
+		}
+		copy(l.Biases, s.layers[i].biases)
+	}
+}
+
+// Snapshot is an opaque, restorable copy of a Network's weights and
+// biases.
+type Snapshot struct {
+	layers []layerSnapshot
+}
+
+type layerSnapshot struct {
+	weights [][]float64
+	biases  []float64
+}
+
+// Argmax returns the index of the largest value in v. It is the
+// standard way to turn a one-hot target or a network's output
+// activations into a predicted class index, and works regardless of
+// whether the values are non-negative (as with Logistic/Softmax
+// outputs) or span negative ranges (as with Tanh/ReLU outputs).
+func Argmax(v []float64) int {
+	idx := 0
+	max := v[0]
+	for i, x := range v {
+		if x > max {
+			max = x
+			idx = i
+		}
+	}
+	return idx
+}
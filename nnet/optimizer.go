@@ -0,0 +1,158 @@
+/*  nn4md - neural net for pulse induction metal detector
+    Copyright (C) 2019 Alexey "FoxyLab" Voronin
+    Email:    support@foxylab.com
+    Website:  https://acdc.foxylab.com
+
+	This program is free software; you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation; either version 3 of the License, or
+    (at your option) any later version.
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+    You should have received a copy of the GNU General Public License
+    along with this program; if not, write to the Free Software
+    Foundation, Inc., 59 Temple Place, Suite 330, Boston, MA  02111-1307 USA
+
+*/
+
+package nnet
+
+import "math"
+
+// Optimizer applies accumulated (already batch-averaged) gradients to a
+// layer's weights and biases. layerIdx identifies the layer within its
+// Network so stateful optimizers (momentum, Adam, RMSProp) can keep
+// per-layer accumulators.
+type Optimizer interface {
+	Update(layerIdx int, layer *Layer, weightGrad [][]float64, biasGrad []float64)
+}
+
+// SGD is gradient descent with optional momentum: v = Momentum*v -
+// LR*grad, applied as w += v.
+type SGD struct {
+	LR       float64
+	Momentum float64
+
+	state map[int]*sgdState
+}
+
+type sgdState struct {
+	weightVelocity [][]float64
+	biasVelocity   []float64
+}
+
+func (o *SGD) Update(layerIdx int, layer *Layer, weightGrad [][]float64, biasGrad []float64) {
+	if o.state == nil {
+		o.state = map[int]*sgdState{}
+	}
+	s, ok := o.state[layerIdx]
+	if !ok {
+		s = &sgdState{weightVelocity: mat2D(len(weightGrad), weightCols(weightGrad)), biasVelocity: mat1D(len(biasGrad), 0)}
+		o.state[layerIdx] = s
+	}
+	for j, row := range weightGrad {
+		for i, g := range row {
+			s.weightVelocity[j][i] = o.Momentum*s.weightVelocity[j][i] - o.LR*g
+			layer.Weights[j][i] += s.weightVelocity[j][i]
+		}
+		s.biasVelocity[j] = o.Momentum*s.biasVelocity[j] - o.LR*biasGrad[j]
+		layer.Biases[j] += s.biasVelocity[j]
+	}
+}
+
+// RMSProp divides each update by a decaying average of squared
+// gradients: cache = Decay*cache + (1-Decay)*grad^2, w -=
+// LR*grad/(sqrt(cache)+Eps).
+type RMSProp struct {
+	LR    float64
+	Decay float64
+	Eps   float64
+
+	state map[int]*rmspropState
+}
+
+type rmspropState struct {
+	weightCache [][]float64
+	biasCache   []float64
+}
+
+func (o *RMSProp) Update(layerIdx int, layer *Layer, weightGrad [][]float64, biasGrad []float64) {
+	if o.state == nil {
+		o.state = map[int]*rmspropState{}
+	}
+	s, ok := o.state[layerIdx]
+	if !ok {
+		s = &rmspropState{weightCache: mat2D(len(weightGrad), weightCols(weightGrad)), biasCache: mat1D(len(biasGrad), 0)}
+		o.state[layerIdx] = s
+	}
+	for j, row := range weightGrad {
+		for i, g := range row {
+			s.weightCache[j][i] = o.Decay*s.weightCache[j][i] + (1-o.Decay)*g*g
+			layer.Weights[j][i] -= o.LR * g / (math.Sqrt(s.weightCache[j][i]) + o.Eps)
+		}
+		gb := biasGrad[j]
+		s.biasCache[j] = o.Decay*s.biasCache[j] + (1-o.Decay)*gb*gb
+		layer.Biases[j] -= o.LR * gb / (math.Sqrt(s.biasCache[j]) + o.Eps)
+	}
+}
+
+// Adam keeps per-weight first and second moment estimates with bias
+// correction: m = Beta1*m + (1-Beta1)*grad, v = Beta2*v +
+// (1-Beta2)*grad^2, w -= LR*m̂/(sqrt(v̂)+Eps).
+type Adam struct {
+	LR    float64
+	Beta1 float64
+	Beta2 float64
+	Eps   float64
+
+	state map[int]*adamState
+}
+
+type adamState struct {
+	weightM, weightV [][]float64
+	biasM, biasV     []float64
+	t                int
+}
+
+func (o *Adam) Update(layerIdx int, layer *Layer, weightGrad [][]float64, biasGrad []float64) {
+	if o.state == nil {
+		o.state = map[int]*adamState{}
+	}
+	s, ok := o.state[layerIdx]
+	if !ok {
+		s = &adamState{
+			weightM: mat2D(len(weightGrad), weightCols(weightGrad)),
+			weightV: mat2D(len(weightGrad), weightCols(weightGrad)),
+			biasM:   mat1D(len(biasGrad), 0),
+			biasV:   mat1D(len(biasGrad), 0),
+		}
+		o.state[layerIdx] = s
+	}
+	s.t++
+	beta1Correction := 1 - math.Pow(o.Beta1, float64(s.t))
+	beta2Correction := 1 - math.Pow(o.Beta2, float64(s.t))
+	for j, row := range weightGrad {
+		for i, g := range row {
+			s.weightM[j][i] = o.Beta1*s.weightM[j][i] + (1-o.Beta1)*g
+			s.weightV[j][i] = o.Beta2*s.weightV[j][i] + (1-o.Beta2)*g*g
+			mHat := s.weightM[j][i] / beta1Correction
+			vHat := s.weightV[j][i] / beta2Correction
+			layer.Weights[j][i] -= o.LR * mHat / (math.Sqrt(vHat) + o.Eps)
+		}
+		gb := biasGrad[j]
+		s.biasM[j] = o.Beta1*s.biasM[j] + (1-o.Beta1)*gb
+		s.biasV[j] = o.Beta2*s.biasV[j] + (1-o.Beta2)*gb*gb
+		mHat := s.biasM[j] / beta1Correction
+		vHat := s.biasV[j] / beta2Correction
+		layer.Biases[j] -= o.LR * mHat / (math.Sqrt(vHat) + o.Eps)
+	}
+}
+
+func weightCols(weightGrad [][]float64) int {
+	if len(weightGrad) == 0 {
+		return 0
+	}
+	return len(weightGrad[0])
+}
@@ -0,0 +1,47 @@
+package nnet
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSGDMomentumAccumulatesVelocity(t *testing.T) {
+	layer := &Layer{Weights: [][]float64{{1}}, Biases: []float64{0}}
+	opt := &SGD{LR: 0.1, Momentum: 0.9}
+
+	opt.Update(0, layer, [][]float64{{1}}, []float64{1})
+	firstWeight := layer.Weights[0][0]
+	if math.Abs(firstWeight-0.9) > 1e-9 {
+		t.Fatalf("after first update: weight = %v, want 0.9", firstWeight)
+	}
+
+	opt.Update(0, layer, [][]float64{{1}}, []float64{1})
+	// v2 = 0.9*(-0.1) - 0.1*1 = -0.19, so the second step moves weight
+	// further than a plain (momentum-less) update would.
+	secondStep := layer.Weights[0][0] - firstWeight
+	if math.Abs(secondStep-(-0.19)) > 1e-9 {
+		t.Errorf("second update step = %v, want -0.19 (momentum carried over)", secondStep)
+	}
+}
+
+func TestAdamMovesWeightTowardNegativeGradient(t *testing.T) {
+	layer := &Layer{Weights: [][]float64{{1}}, Biases: []float64{0}}
+	opt := &Adam{LR: 0.1, Beta1: 0.9, Beta2: 0.999, Eps: 1e-8}
+
+	opt.Update(0, layer, [][]float64{{1}}, []float64{0})
+	if layer.Weights[0][0] >= 1 {
+		t.Errorf("Adam update with positive gradient: weight = %v, want < 1", layer.Weights[0][0])
+	}
+}
+
+func TestRMSPropDividesByGradientMagnitude(t *testing.T) {
+	layer := &Layer{Weights: [][]float64{{1}}, Biases: []float64{0}}
+	opt := &RMSProp{LR: 0.1, Decay: 0.9, Eps: 1e-8}
+
+	opt.Update(0, layer, [][]float64{{10}}, []float64{0})
+	// cache = 0.1*100 = 10, update ~= LR*10/sqrt(10) = 0.1*sqrt(10)
+	want := 1 - 0.1*10/math.Sqrt(10)
+	if math.Abs(layer.Weights[0][0]-want) > 1e-7 {
+		t.Errorf("RMSProp update: weight = %v, want %v", layer.Weights[0][0], want)
+	}
+}